@@ -0,0 +1,53 @@
+package consensus
+
+import (
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+)
+
+// blockDelayTimeout bounds how long an announced head is trusted before it
+// ages out of a peer's tree, mirroring the LES light fetcher.
+const blockDelayTimeout = 10 * time.Second
+
+// maxAnnouncedPerPeer caps how many heads a single peer's tree retains.
+const maxAnnouncedPerPeer = 20
+
+type announcedHead struct {
+	Hash       common.Hash
+	Number     uint64
+	ParentHash common.Hash
+	at         time.Time
+}
+
+// peerTree is the set of heads a single peer has announced, used to decide
+// which peers are worth asking for a given ancestor range.
+type peerTree struct {
+	nodes []announcedHead
+}
+
+func (t *peerTree) prune(now time.Time) {
+	fresh := t.nodes[:0]
+	for _, n := range t.nodes {
+		if now.Sub(n.at) <= blockDelayTimeout {
+			fresh = append(fresh, n)
+		}
+	}
+	t.nodes = fresh
+}
+
+func (t *peerTree) add(node announcedHead) {
+	t.nodes = append(t.nodes, node)
+	if len(t.nodes) > maxAnnouncedPerPeer {
+		t.nodes = t.nodes[len(t.nodes)-maxAnnouncedPerPeer:]
+	}
+}
+
+func (t *peerTree) covers(from, to uint64) bool {
+	for _, n := range t.nodes {
+		if n.Number >= from && n.Number <= to {
+			return true
+		}
+	}
+	return false
+}
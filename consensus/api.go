@@ -0,0 +1,247 @@
+package consensus
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+const cleanupInterval = 5 * time.Second
+
+// responseBufferSize is the default buffer depth for VerifyHeaderResponses
+// and VerifyUnclesResponses, so a producer-side send only has to fall back
+// to the ResponsePolicy once a genuinely stalled consumer has let this many
+// responses pile up.
+const responseBufferSize = 256
+
+// ErrResponseChannelFull is returned by HeaderVerificationWithContext when
+// ResponsePolicyReject is in effect and the response buffer has no room.
+var ErrResponseChannelFull = errors.New("verify header response channel is full")
+
+// ResponsePolicy controls what SendVerifyHeaderResponse does once
+// VerifyHeaderResponses' buffer is full.
+type ResponsePolicy int
+
+const (
+	// ResponsePolicyDropOldest discards the oldest buffered response to make
+	// room for the new one. The default: a slow consumer loses history
+	// instead of stalling the coordinator or worker pool.
+	ResponsePolicyDropOldest ResponsePolicy = iota
+	// ResponsePolicyReject refuses to buffer past capacity; callers using
+	// HeaderVerificationWithContext see ErrResponseChannelFull instead.
+	ResponsePolicyReject
+)
+
+// API is the channel-based boundary between the p2p/downloader layer and
+// the consensus process goroutine. Callers push requests in and read
+// responses out; they never touch process.Consensus's internal state
+// directly.
+type API struct {
+	Chain ChainReader
+
+	VerifyHeaderRequests  chan VerifyHeaderRequest
+	VerifyHeaderResponses chan VerifyHeaderResponse
+
+	HeadersRequests chan HeadersRequest
+	HeaderResponses chan HeaderResponse
+
+	PeerHeadersRequests   chan PeerHeadersRequest
+	CancelHeadersRequests chan CancelHeadersRequest
+
+	VerifyUnclesRequests  chan VerifyUnclesRequest
+	VerifyUnclesResponses chan VerifyUnclesResponse
+
+	ProcessingRequestsMu sync.Mutex
+	ProcessingRequests   map[uint64]map[uint64]*VerifyRequest
+
+	ProcessingUnclesRequestsMu sync.Mutex
+	ProcessingUnclesRequests   map[uint64]*UnclesVerifyRequest
+
+	CleanupTicker  *time.Ticker
+	CleanupCh      chan FinishedRequest
+	CancelledCh    chan CancelledRequest
+	ResponsePolicy ResponsePolicy
+
+	cacheMu sync.RWMutex
+	cache   map[common.Hash]*types.Header
+
+	announcedMu sync.Mutex
+	announced   map[string]*peerTree
+
+	badPeersMu sync.Mutex
+	badPeers   map[string]struct{}
+}
+
+// NewAPI creates an API whose VerifyHeaderResponses and VerifyUnclesResponses
+// channels are buffered to responseBufferSize; all its other channels are
+// unbuffered. The header cache starts empty.
+func NewAPI(config *params.ChainConfig) *API {
+	return &API{
+		VerifyHeaderRequests:  make(chan VerifyHeaderRequest),
+		VerifyHeaderResponses: make(chan VerifyHeaderResponse, responseBufferSize),
+
+		HeadersRequests: make(chan HeadersRequest),
+		HeaderResponses: make(chan HeaderResponse),
+
+		PeerHeadersRequests:   make(chan PeerHeadersRequest),
+		CancelHeadersRequests: make(chan CancelHeadersRequest),
+
+		VerifyUnclesRequests:  make(chan VerifyUnclesRequest),
+		VerifyUnclesResponses: make(chan VerifyUnclesResponse, responseBufferSize),
+
+		ProcessingRequests:       make(map[uint64]map[uint64]*VerifyRequest),
+		ProcessingUnclesRequests: make(map[uint64]*UnclesVerifyRequest),
+
+		CleanupTicker:  time.NewTicker(cleanupInterval),
+		CleanupCh:      make(chan FinishedRequest),
+		CancelledCh:    make(chan CancelledRequest),
+		ResponsePolicy: ResponsePolicyDropOldest,
+
+		cache: make(map[common.Hash]*types.Header),
+
+		announced: make(map[string]*peerTree),
+		badPeers:  make(map[string]struct{}),
+	}
+}
+
+// GetCachedHeader returns a previously verified header by hash, or nil if
+// it isn't known.
+func (a *API) GetCachedHeader(hash common.Hash, number uint64) *types.Header {
+	a.cacheMu.RLock()
+	defer a.cacheMu.RUnlock()
+
+	h, ok := a.cache[hash]
+	if !ok || h.Number.Uint64() != number {
+		return nil
+	}
+
+	return h
+}
+
+// CacheHeader records header as verified so future requests can short
+// circuit on it.
+func (a *API) CacheHeader(task HeaderTask) {
+	a.cacheMu.Lock()
+	defer a.cacheMu.Unlock()
+
+	a.cache[task.Hash] = task.Header
+}
+
+// SendVerifyHeaderResponse delivers resp without blocking the sender. Once
+// VerifyHeaderResponses' buffer is full it applies ResponsePolicy: drop the
+// oldest buffered response to make room, or refuse the send outright. It
+// reports whether resp was (eventually) buffered.
+func (a *API) SendVerifyHeaderResponse(resp VerifyHeaderResponse) bool {
+	select {
+	case a.VerifyHeaderResponses <- resp:
+		return true
+	default:
+	}
+
+	if a.ResponsePolicy == ResponsePolicyReject {
+		return false
+	}
+
+	select {
+	case <-a.VerifyHeaderResponses:
+	default:
+	}
+
+	select {
+	case a.VerifyHeaderResponses <- resp:
+		return true
+	default:
+		return false
+	}
+}
+
+// SendVerifyUnclesResponse delivers resp without blocking the sender,
+// applying the same ResponsePolicy as SendVerifyHeaderResponse once
+// VerifyUnclesResponses' buffer is full. It reports whether resp was
+// (eventually) buffered.
+func (a *API) SendVerifyUnclesResponse(resp VerifyUnclesResponse) bool {
+	select {
+	case a.VerifyUnclesResponses <- resp:
+		return true
+	default:
+	}
+
+	if a.ResponsePolicy == ResponsePolicyReject {
+		return false
+	}
+
+	select {
+	case <-a.VerifyUnclesResponses:
+	default:
+	}
+
+	select {
+	case a.VerifyUnclesResponses <- resp:
+		return true
+	default:
+		return false
+	}
+}
+
+// ResponseBufferFull reports whether VerifyHeaderResponses currently has no
+// spare capacity.
+func (a *API) ResponseBufferFull() bool {
+	return len(a.VerifyHeaderResponses) >= cap(a.VerifyHeaderResponses)
+}
+
+// AnnounceHead records that peerID has announced hash/number as its head,
+// linked to parentHash, so ancestor fetches know this peer can plausibly
+// answer for that range.
+func (a *API) AnnounceHead(peerID string, hash common.Hash, number uint64, parentHash common.Hash) {
+	a.announcedMu.Lock()
+	defer a.announcedMu.Unlock()
+
+	tree, ok := a.announced[peerID]
+	if !ok {
+		tree = &peerTree{}
+		a.announced[peerID] = tree
+	}
+
+	now := time.Now()
+	tree.prune(now)
+	tree.add(announcedHead{Hash: hash, Number: number, ParentHash: parentHash, at: now})
+}
+
+// PeersCovering returns the known-good peers whose announced tree covers
+// any block number in [from, to].
+func (a *API) PeersCovering(from, to uint64) []string {
+	a.announcedMu.Lock()
+	defer a.announcedMu.Unlock()
+
+	a.badPeersMu.Lock()
+	defer a.badPeersMu.Unlock()
+
+	now := time.Now()
+
+	var peers []string
+	for peerID, tree := range a.announced {
+		if _, bad := a.badPeers[peerID]; bad {
+			continue
+		}
+
+		tree.prune(now)
+		if tree.covers(from, to) {
+			peers = append(peers, peerID)
+		}
+	}
+
+	return peers
+}
+
+// DropPeer marks peerID as unreliable so it is no longer selected as an
+// ancestor source.
+func (a *API) DropPeer(peerID string) {
+	a.badPeersMu.Lock()
+	defer a.badPeersMu.Unlock()
+
+	a.badPeers[peerID] = struct{}{}
+}
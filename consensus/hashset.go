@@ -0,0 +1,28 @@
+package consensus
+
+import "github.com/ledgerwatch/turbo-geth/common"
+
+// HashSet is a minimal set of block hashes. It exists because the uncle
+// verification logic only ever needs membership checks, not the full
+// bookkeeping a map of maps provides.
+type HashSet map[common.Hash]struct{}
+
+// NewHashSet builds a HashSet from the given hashes.
+func NewHashSet(hashes ...common.Hash) HashSet {
+	s := make(HashSet, len(hashes))
+	for _, h := range hashes {
+		s.Add(h)
+	}
+	return s
+}
+
+// Add inserts h into the set.
+func (s HashSet) Add(h common.Hash) {
+	s[h] = struct{}{}
+}
+
+// Contains reports whether h is a member of the set.
+func (s HashSet) Contains(h common.Hash) bool {
+	_, ok := s[h]
+	return ok
+}
@@ -0,0 +1,100 @@
+package process
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+)
+
+// TestRouteAncestorResponseSwallowsSinglePeerError exercises the
+// fastest-wins multi-source resolution: a failing peer must not fail the
+// whole batch while other peers are still outstanding, a later success
+// still resolves the fetch and cancels the rest, and a conflicting
+// duplicate after resolution drops the offending peer.
+func TestRouteAncestorResponseSwallowsSinglePeerError(t *testing.T) {
+	c := &Consensus{
+		API:             consensus.NewAPI(nil),
+		ancestorFetches: make(map[uint64]*ancestorFetch),
+	}
+
+	c.ancestorFetches[1] = &ancestorFetch{
+		peers:    []string{"p1", "p2", "p3"},
+		errored:  make(map[string]struct{}),
+		accepted: make(map[uint64]common.Hash),
+		deadline: time.Now().Add(time.Minute),
+	}
+
+	// p1 errors: batch must not fail yet, other peers are still pending.
+	drop := c.routeAncestorResponse(consensus.HeaderResponse{ID: 1, PeerID: "p1", Err: errors.New("no route to peer")})
+	if !drop {
+		t.Fatal("a single errored peer out of three must not resolve the fetch")
+	}
+
+	good := consensus.NewHeaderTask(&types.Header{Number: big.NewInt(5), GasUsed: 1})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var cancelled consensus.CancelHeadersRequest
+	go func() {
+		defer wg.Done()
+		cancelled = <-c.API.CancelHeadersRequests
+	}()
+
+	// p2 succeeds: this is the authoritative response and should cancel p3.
+	drop = c.routeAncestorResponse(consensus.HeaderResponse{ID: 1, PeerID: "p2", Headers: []consensus.HeaderTask{good}})
+	if drop {
+		t.Fatal("the first successful response must resolve the fetch, not be dropped")
+	}
+
+	wg.Wait()
+	if cancelled.ID != 1 {
+		t.Fatalf("expected a cancel request for ID 1, got %+v", cancelled)
+	}
+
+	// p3 now responds with a conflicting header for the same block number:
+	// it must be dropped as a bad peer rather than accepted.
+	conflicting := consensus.NewHeaderTask(&types.Header{Number: big.NewInt(5), GasUsed: 2})
+	// Announce p3 so PeersCovering would list it unless it is dropped.
+	c.API.AnnounceHead("p3", conflicting.Hash, 5, common.Hash{})
+	drop = c.routeAncestorResponse(consensus.HeaderResponse{ID: 1, PeerID: "p3", Headers: []consensus.HeaderTask{conflicting}})
+	if !drop {
+		t.Fatal("a response arriving after resolution must be dropped, not merged")
+	}
+
+	for _, peerID := range c.API.PeersCovering(5, 5) {
+		if peerID == "p3" {
+			t.Fatal("a peer sending a conflicting header after resolution must be dropped")
+		}
+	}
+}
+
+// TestRouteAncestorResponseFailsOnceEveryPeerErrors checks the other half of
+// the same fix: the batch should only fail once every dispatched peer has
+// errored, not on the first one.
+func TestRouteAncestorResponseFailsOnceEveryPeerErrors(t *testing.T) {
+	c := &Consensus{
+		API:             consensus.NewAPI(nil),
+		ancestorFetches: make(map[uint64]*ancestorFetch),
+	}
+
+	c.ancestorFetches[7] = &ancestorFetch{
+		peers:    []string{"p1", "p2"},
+		errored:  make(map[string]struct{}),
+		accepted: make(map[uint64]common.Hash),
+		deadline: time.Now().Add(time.Minute),
+	}
+
+	if drop := c.routeAncestorResponse(consensus.HeaderResponse{ID: 7, PeerID: "p1", Err: errors.New("timeout")}); !drop {
+		t.Fatal("first of two peers erroring must not fail the batch yet")
+	}
+
+	if drop := c.routeAncestorResponse(consensus.HeaderResponse{ID: 7, PeerID: "p2", Err: errors.New("timeout")}); drop {
+		t.Fatal("once every dispatched peer has errored, the batch must fail")
+	}
+}
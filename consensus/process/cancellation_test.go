@@ -0,0 +1,130 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// TestWatchCancellationExitsOnNormalCompletion reproduces the goroutine-leak
+// fix: once a request has left ProcessingRequests through cleanupRequest (a
+// normal completion, not a context cancellation), watchCancellation must
+// exit rather than keep waiting on ctx.Done() for the rest of ctx's
+// lifetime, and a later ctx cancellation must not produce a spurious
+// CancelledRequest for the already-finished request.
+func TestWatchCancellationExitsOnNormalCompletion(t *testing.T) {
+	exit := make(chan struct{})
+	defer close(exit)
+
+	// No coordinator goroutine: it also receives on CancelledCh and would
+	// race the test for any CancelledRequest.
+	c := &Consensus{API: consensus.NewAPI(nil), exit: exit}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	task := newTask(5)
+	c.addVerifyHeaderRequest(1, task, false, nil, ctx, nil, 0)
+
+	// Normal completion: the request leaves ProcessingRequests before ctx is
+	// ever cancelled.
+	c.cleanupRequest(1, 5)
+
+	cancel()
+
+	select {
+	case cr := <-c.API.CancelledCh:
+		t.Fatalf("watchCancellation sent a spurious CancelledRequest after normal completion: %+v", cr)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// TestWatchCancellationForwardsContextCancellation checks the other half of
+// watchCancellation: a still-pending request's ctx cancellation must still
+// be forwarded onto CancelledCh.
+func TestWatchCancellationForwardsContextCancellation(t *testing.T) {
+	exit := make(chan struct{})
+	defer close(exit)
+
+	// No coordinator goroutine: it also receives on CancelledCh and would
+	// race the test for the CancelledRequest.
+	c := &Consensus{API: consensus.NewAPI(nil), exit: exit}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	task := newTask(5)
+	c.addVerifyHeaderRequest(1, task, false, nil, ctx, nil, 0)
+
+	cancel()
+
+	select {
+	case cr := <-c.API.CancelledCh:
+		if cr.ReqID != 1 || cr.BlockNumber != 5 {
+			t.Fatalf("unexpected CancelledRequest: %+v", cr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for CancelledRequest")
+	}
+}
+
+// TestHeaderVerificationWithContextAttachesContext checks that
+// HeaderVerificationWithContext attaches ctx to the submitted request
+// before handing it to the coordinator.
+func TestHeaderVerificationWithContextAttachesContext(t *testing.T) {
+	// No coordinator goroutine: it also receives on VerifyHeaderRequests and
+	// would race the test for the submitted request.
+	c := &Consensus{API: consensus.NewAPI(nil)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req := consensus.VerifyHeaderRequest{ID: 5, Headers: []consensus.HeaderTask{newTask(1)}, Seal: []bool{false}}
+
+	received := make(chan consensus.VerifyHeaderRequest, 1)
+	go func() {
+		received <- <-c.API.VerifyHeaderRequests
+	}()
+
+	if err := c.HeaderVerificationWithContext(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if got.Context != ctx {
+			t.Fatal("HeaderVerificationWithContext did not attach ctx to the submitted request")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the request to be submitted")
+	}
+}
+
+// TestHeaderVerificationWithContextRejectsWhenResponseBufferFull checks that
+// under ResponsePolicyReject, a full VerifyHeaderResponses buffer is
+// rejected up front with ErrResponseChannelFull instead of submitting a
+// request whose response would have nowhere to land.
+func TestHeaderVerificationWithContextRejectsWhenResponseBufferFull(t *testing.T) {
+	v := &blockingVerifier{started: make(chan struct{}, 1), release: make(chan struct{})}
+	close(v.release)
+
+	exit := make(chan struct{})
+	defer close(exit)
+
+	c := NewConsensusProcess(v, &params.ChainConfig{}, exit)
+	c.API.ResponsePolicy = consensus.ResponsePolicyReject
+
+	for i := 0; i < cap(c.API.VerifyHeaderResponses); i++ {
+		c.API.VerifyHeaderResponses <- consensus.VerifyHeaderResponse{ID: uint64(i)}
+	}
+
+	req := consensus.VerifyHeaderRequest{ID: 99, Headers: []consensus.HeaderTask{newTask(1)}, Seal: []bool{false}}
+
+	err := c.HeaderVerificationWithContext(context.Background(), req)
+	if !errors.Is(err, consensus.ErrResponseChannelFull) {
+		t.Fatalf("expected ErrResponseChannelFull, got %v", err)
+	}
+}
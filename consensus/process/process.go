@@ -1,8 +1,11 @@
 package process
 
 import (
+	"context"
 	"errors"
+	"runtime"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/ledgerwatch/turbo-geth/common"
@@ -15,29 +18,82 @@ import (
 type Consensus struct {
 	Server         consensus.Verifier
 	*consensus.API // remote Engine
+
+	exit chan struct{}
+
+	workerPoolSize int
+	verifyTasks    chan verifyTask
+	uncleTasks     chan uncleVerifyTask
+
+	ancestorFetchesMu sync.Mutex
+	ancestorFetches   map[uint64]*ancestorFetch
 }
 
 const ttl = time.Minute
 
+// maxAncestorSources caps how many peers a single ancestor range is
+// dispatched to concurrently.
+const maxAncestorSources = 3
+
+// defaultVerifyQueueSize bounds how many ready-to-verify tasks the
+// coordinator can have outstanding. It protects against a peer that keeps
+// completing ancestor chains faster than the worker pool can verify them.
+const defaultVerifyQueueSize = 1024
+
+// defaultUnclesQueueSize bounds how many ready-to-verify uncle sets the
+// coordinator can have outstanding, mirroring defaultVerifyQueueSize.
+const defaultUnclesQueueSize = 256
+
 var (
-	errEmptyHeader  = errors.New("an empty header")
-	errNothingToAsk = errors.New("nothing to ask")
+	errEmptyHeader          = errors.New("an empty header")
+	errNothingToAsk         = errors.New("nothing to ask")
+	errVerifyQueueFull      = errors.New("verify queue is full")
+	errAncestorBlockUnknown = errors.New("ancestor block not locally available for uncle verification")
 )
 
-func NewConsensusProcess(v consensus.Verifier, config *params.ChainConfig, exit chan struct{}) *Consensus {
+// Option configures a Consensus at construction time.
+type Option func(*Consensus)
+
+// WithWorkerPoolSize overrides the number of goroutines that run
+// consensus.Verifier.Verify concurrently. Defaults to runtime.NumCPU().
+func WithWorkerPoolSize(n int) Option {
+	return func(c *Consensus) {
+		c.workerPoolSize = n
+	}
+}
+
+func NewConsensusProcess(v consensus.Verifier, config *params.ChainConfig, exit chan struct{}, opts ...Option) *Consensus {
 	c := &Consensus{
-		Server: v,
-		API:    consensus.NewAPI(config),
+		Server:          v,
+		API:             consensus.NewAPI(config),
+		exit:            exit,
+		workerPoolSize:  runtime.NumCPU(),
+		ancestorFetches: make(map[uint64]*ancestorFetch),
 	}
 
-	// event loop
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.verifyTasks = make(chan verifyTask, defaultVerifyQueueSize)
+	c.uncleTasks = make(chan uncleVerifyTask, defaultUnclesQueueSize)
+
+	// verifier worker pool: the CPU-bound Verify/VerifyUncles calls run off
+	// the coordinator goroutine so one slow check can't stall ingress or
+	// cleanup handling.
+	for i := 0; i < c.workerPoolSize; i++ {
+		go c.verifyWorker(exit)
+	}
+
+	// coordinator: I/O, ancestor resolution, and ProcessingRequests mutation.
+	// Verify itself happens on the worker pool started above.
 	go func() {
 	eventLoop:
 		for {
 			select {
 			case req := <-c.API.VerifyHeaderRequests:
 				if len(req.Headers) == 0 {
-					c.API.VerifyHeaderResponses <- consensus.VerifyHeaderResponse{req.ID, common.Hash{}, errEmptyHeader}
+					c.API.SendVerifyHeaderResponse(consensus.VerifyHeaderResponse{req.ID, common.Hash{}, errEmptyHeader})
 					continue
 				}
 
@@ -53,61 +109,89 @@ func NewConsensusProcess(v consensus.Verifier, config *params.ChainConfig, exit
 				}
 
 				sort.Slice(reqHeaders, func(i, j int) bool {
-					return reqHeaders[i].header.Number.Cmp(reqHeaders[j].header.Number) == -1
+					return reqHeaders[i].task.Header.Number.Cmp(reqHeaders[j].task.Header.Number) == -1
 				})
 
-				req.Headers = make([]*types.Header, len(reqHeaders))
+				req.Headers = make([]consensus.HeaderTask, len(reqHeaders))
 				req.Seal = make([]bool, len(reqHeaders))
 				for i := range reqHeaders {
-					req.Headers[i] = reqHeaders[i].header
+					req.Headers[i] = reqHeaders[i].task
 					req.Seal[i] = reqHeaders[i].seal
 				}
 
 				ancestorsReqs := make([]consensus.HeadersRequest, 0, len(req.Headers))
 
-				for i, header := range req.Headers {
-					if header == nil {
-						c.API.VerifyHeaderResponses <- consensus.VerifyHeaderResponse{req.ID, common.Hash{}, errEmptyHeader}
+				for i, task := range req.Headers {
+					if task.Header == nil {
+						c.API.SendVerifyHeaderResponse(consensus.VerifyHeaderResponse{req.ID, common.Hash{}, errEmptyHeader})
 						continue eventLoop
 					}
 
 					// Short circuit if the header is known
-					if h := c.API.GetCachedHeader(header.Hash(), header.Number.Uint64()); h != nil {
-						c.API.VerifyHeaderResponses <- consensus.VerifyHeaderResponse{req.ID, header.Hash(), nil}
+					if h := c.API.GetCachedHeader(task.Hash, task.Header.Number.Uint64()); h != nil {
+						c.API.SendVerifyHeaderResponse(consensus.VerifyHeaderResponse{req.ID, task.Hash, nil})
 						continue
 					}
 
-					knownParentsSlice, parentsToValidate, ancestorsReq := c.requestParentHeaders(req.ID, header, req.Headers)
+					knownParentsSlice, parentsToValidate, ancestorsReq := c.requestParentHeaders(req.ID, task, req.Headers)
 					if ancestorsReq != nil {
 						ancestorsReqs = append(ancestorsReqs, *ancestorsReq)
 					}
 
-					err := c.verifyByRequest(req.ID, header, req.Seal[i], parentsToValidate, knownParentsSlice)
+					err := c.verifyByRequest(req.ID, task, req.Seal[i], parentsToValidate, knownParentsSlice)
 					if errors.Is(err, errNotAllParents) {
-						c.addVerifyHeaderRequest(req.ID, header, req.Seal[i], req.Deadline, knownParentsSlice, parentsToValidate)
+						c.addVerifyHeaderRequest(req.ID, task, req.Seal[i], req.Deadline, req.Context, knownParentsSlice, parentsToValidate)
 					}
 				}
 
-				ancestorsReq, err := sumHeadersRequestsInRange(req.ID, req.Headers[0].Number.Uint64(), ancestorsReqs...)
+				ancestorsReq, err := sumHeadersRequestsInRange(req.ID, req.Headers[0].Header.Number.Uint64(), ancestorsReqs...)
 				if err != nil {
-					log.Error("can't request header ancestors", "reqID", req.ID, "number", req.Headers[0].Number.Uint64(), "err", err)
+					log.Error("can't request header ancestors", "reqID", req.ID, "number", req.Headers[0].Header.Number.Uint64(), "err", err)
 					continue
 				}
 
-				c.API.HeadersRequests <- ancestorsReq
+				c.dispatchHeadersRequest(ancestorsReq)
 
 			case parentResp := <-c.API.HeaderResponses:
+				if c.routeAncestorResponse(parentResp) {
+					continue
+				}
+
+				c.API.ProcessingUnclesRequestsMu.Lock()
+				uncleReq, isUncleAncestors := c.API.ProcessingUnclesRequests[parentResp.ID]
+				c.API.ProcessingUnclesRequestsMu.Unlock()
+
+				if isUncleAncestors {
+					c.verifyUncles(uncleReq, parentResp)
+					continue
+				}
+
 				if parentResp.Err != nil {
-					c.API.VerifyHeaderResponses <- consensus.VerifyHeaderResponse{parentResp.ID, parentResp.Hash, parentResp.Err}
+					c.API.SendVerifyHeaderResponse(consensus.VerifyHeaderResponse{parentResp.ID, parentResp.Hash, parentResp.Err})
 
 					c.API.ProcessingRequestsMu.Lock()
+					for _, req := range c.API.ProcessingRequests[parentResp.ID] {
+						close(req.Done)
+					}
 					delete(c.API.ProcessingRequests, parentResp.ID)
 					c.API.ProcessingRequestsMu.Unlock()
 
 					continue
 				}
 
-				c.VerifyRequestsCommonAncestor(parentResp.ID, parentResp.Headers)
+				// VerifyRequestsCommonAncestor blocks on each header's real
+				// Verify result to chain-verify the batch correctly; run it
+				// off the coordinator so that wait can't stall ingress.
+				go c.VerifyRequestsCommonAncestor(parentResp.ID, parentResp.Headers)
+
+			case uncleReq := <-c.API.VerifyUnclesRequests:
+				c.requestUncleAncestors(uncleReq)
+
+			// a pending request's Context was cancelled; don't wait for
+			// CleanupTicker to notice the (still far-off) Deadline.
+			case cancelled := <-c.API.CancelledCh:
+				c.API.SendVerifyHeaderResponse(consensus.VerifyHeaderResponse{cancelled.ReqID, common.Hash{}, cancelled.Err})
+				c.cleanupRequest(cancelled.ReqID, cancelled.BlockNumber)
 
 			// cleanup by timeout
 			case <-c.API.CleanupTicker.C:
@@ -135,8 +219,8 @@ func NewConsensusProcess(v consensus.Verifier, config *params.ChainConfig, exit
 }
 
 type reqHeader struct {
-	header *types.Header
-	seal   bool
+	task consensus.HeaderTask
+	seal bool
 }
 
 func (c *Consensus) cleanup() {
@@ -145,19 +229,254 @@ func (c *Consensus) cleanup() {
 	c.API.ProcessingRequestsMu.Lock()
 
 	for reqID, reqBlocks := range c.API.ProcessingRequests {
+		expired := false
+
 		for _, req := range reqBlocks {
 			if req.Deadline.Before(now) {
-				c.API.VerifyHeaderResponses <- consensus.VerifyHeaderResponse{reqID, req.Header.Hash(), errors.New("timeout")}
+				c.API.SendVerifyHeaderResponse(consensus.VerifyHeaderResponse{reqID, req.Header.Hash, errors.New("timeout")})
+				expired = true
+			}
+		}
 
-				delete(c.API.ProcessingRequests, reqID)
+		if expired {
+			for _, req := range reqBlocks {
+				close(req.Done)
 			}
+
+			delete(c.API.ProcessingRequests, reqID)
 		}
 	}
 
 	c.API.ProcessingRequestsMu.Unlock()
+
+	c.API.ProcessingUnclesRequestsMu.Lock()
+
+	for reqID, req := range c.API.ProcessingUnclesRequests {
+		if req.Deadline.Before(now) {
+			c.API.SendVerifyUnclesResponse(consensus.VerifyUnclesResponse{reqID, req.Block.Hash(), errors.New("timeout")})
+
+			delete(c.API.ProcessingUnclesRequests, reqID)
+		}
+	}
+
+	c.API.ProcessingUnclesRequestsMu.Unlock()
+
+	c.ancestorFetchesMu.Lock()
+
+	for reqID, fetch := range c.ancestorFetches {
+		if fetch.deadline.Before(now) {
+			delete(c.ancestorFetches, reqID)
+		}
+	}
+
+	c.ancestorFetchesMu.Unlock()
+}
+
+// ancestorFetch tracks a HeadersRequest dispatched to multiple peers: which
+// peers it went to, which of them have already errored, whether a response
+// has already been accepted, and the headers accepted so later responses
+// can be checked for conflicts.
+type ancestorFetch struct {
+	peers    []string
+	errored  map[string]struct{}
+	resolved bool
+	accepted map[uint64]common.Hash
+	deadline time.Time
+}
+
+// dispatchHeadersRequest sends req to up to maxAncestorSources peers whose
+// announced tree covers the requested range, falling back to the single implicit oracle
+// (the legacy HeadersRequests channel) when no peer is known to cover it.
+// The sends themselves run off the coordinator goroutine: HeadersRequests,
+// PeerHeadersRequests and CancelHeadersRequests are unbuffered, and a p2p
+// layer that isn't immediately ready to receive must not block header
+// ingestion, uncle requests, or cleanup the way a slow Verify call used to.
+// If a send never completes, the pending verify or uncle request still
+// times out through its own Deadline in cleanup.
+func (c *Consensus) dispatchHeadersRequest(req consensus.HeadersRequest) {
+	from := req.HighestBlockNumber - req.Number + 1
+
+	peers := c.API.PeersCovering(from, req.HighestBlockNumber)
+	if len(peers) == 0 {
+		go func() { c.API.HeadersRequests <- req }()
+		return
+	}
+
+	if len(peers) > maxAncestorSources {
+		peers = peers[:maxAncestorSources]
+	}
+
+	c.ancestorFetchesMu.Lock()
+	c.ancestorFetches[req.ID] = &ancestorFetch{
+		peers:    peers,
+		errored:  make(map[string]struct{}),
+		accepted: make(map[uint64]common.Hash),
+		deadline: time.Now().Add(ttl),
+	}
+	c.ancestorFetchesMu.Unlock()
+
+	go func() {
+		for _, peerID := range peers {
+			c.API.PeerHeadersRequests <- consensus.PeerHeadersRequest{HeadersRequest: req, PeerID: peerID}
+		}
+	}()
+}
+
+// routeAncestorResponse applies fastest-wins resolution to a multi-source
+// ancestor fetch: the first successful response is accepted and cancels the
+// rest, and a single peer erroring out does not fail the batch as long as
+// another dispatched peer might still answer. It reports whether resp was a
+// duplicate (stale, conflicting, or a non-final error) that the caller
+// should drop rather than treat as the fetch's outcome.
+func (c *Consensus) routeAncestorResponse(resp consensus.HeaderResponse) bool {
+	c.ancestorFetchesMu.Lock()
+	fetch, tracked := c.ancestorFetches[resp.ID]
+	if !tracked {
+		c.ancestorFetchesMu.Unlock()
+		return false
+	}
+
+	if fetch.resolved {
+		conflict := resp.Err == nil && conflictsWith(fetch.accepted, resp.Headers)
+		c.ancestorFetchesMu.Unlock()
+
+		if conflict {
+			c.API.DropPeer(resp.PeerID)
+		}
+
+		return true
+	}
+
+	if resp.Err != nil {
+		fetch.errored[resp.PeerID] = struct{}{}
+		allErrored := len(fetch.errored) >= len(fetch.peers)
+		c.ancestorFetchesMu.Unlock()
+
+		// Swallow the error and keep waiting for the other peers unless
+		// every peer this range was dispatched to has now errored.
+		return !allErrored
+	}
+
+	fetch.resolved = true
+	for _, task := range resp.Headers {
+		fetch.accepted[task.Header.Number.Uint64()] = task.Hash
+	}
+
+	remaining := make([]string, 0, len(fetch.peers))
+	for _, peerID := range fetch.peers {
+		if peerID != resp.PeerID {
+			remaining = append(remaining, peerID)
+		}
+	}
+	c.ancestorFetchesMu.Unlock()
+
+	if len(remaining) > 0 {
+		// Off the coordinator goroutine for the same reason as the dispatch
+		// sends in dispatchHeadersRequest: a p2p layer that isn't ready to
+		// receive must not stall the coordinator.
+		go func() { c.API.CancelHeadersRequests <- consensus.CancelHeadersRequest{resp.ID, remaining} }()
+	}
+
+	return false
+}
+
+func conflictsWith(accepted map[uint64]common.Hash, headers []consensus.HeaderTask) bool {
+	for _, task := range headers {
+		if hash, ok := accepted[task.Header.Number.Uint64()]; ok && hash != task.Hash {
+			return true
+		}
+	}
+	return false
 }
 
-func (c *Consensus) VerifyRequestsCommonAncestor(reqID uint64, headers []*types.Header) {
+// unclesAncestorDepth is the number of direct ancestors of a block's parent
+// that are eligible to contribute uncles, matching classical Ethereum's
+// uncle-inclusion window.
+const unclesAncestorDepth = 7
+
+func (c *Consensus) requestUncleAncestors(req consensus.VerifyUnclesRequest) {
+	if req.Deadline == nil {
+		t := time.Now().Add(ttl)
+		req.Deadline = &t
+	}
+
+	parent := req.Block.Header()
+
+	c.API.ProcessingUnclesRequestsMu.Lock()
+	c.API.ProcessingUnclesRequests[req.ID] = &consensus.UnclesVerifyRequest{
+		ID:        req.ID,
+		Block:     req.Block,
+		Deadline:  req.Deadline,
+		Ancestors: consensus.NewHashSet(),
+		Uncles:    consensus.NewHashSet(),
+	}
+	c.API.ProcessingUnclesRequestsMu.Unlock()
+
+	c.dispatchHeadersRequest(consensus.HeadersRequest{
+		req.ID,
+		parent.ParentHash,
+		parent.Number.Uint64() - 1,
+		unclesAncestorDepth,
+	})
+}
+
+// uncleVerifyTask is an uncle set whose ancestors are fully resolved and is
+// ready for the (CPU-bound) Server.VerifyUncles call, handed off from the
+// coordinator to the worker pool the same way verifyTask is.
+type uncleVerifyTask struct {
+	Req *consensus.UnclesVerifyRequest
+}
+
+// verifyUncles runs on the coordinator goroutine: it does the I/O-ish work
+// of turning resolved ancestor headers into ancestor/uncle sets, then hands
+// the actual Server.VerifyUncles call off to the worker pool rather than
+// running it inline.
+func (c *Consensus) verifyUncles(req *consensus.UnclesVerifyRequest, resp consensus.HeaderResponse) {
+	c.API.ProcessingUnclesRequestsMu.Lock()
+	delete(c.API.ProcessingUnclesRequests, req.ID)
+	c.API.ProcessingUnclesRequestsMu.Unlock()
+
+	if resp.Err != nil {
+		c.API.SendVerifyUnclesResponse(consensus.VerifyUnclesResponse{req.ID, resp.Hash, resp.Err})
+		return
+	}
+
+	for _, task := range resp.Headers {
+		req.Ancestors.Add(task.Hash)
+
+		// Ancestor headers came over the network, not from guaranteed-local
+		// blocks. An ancestor this node hasn't imported yet means its
+		// included uncles are unknown, so includedUncles would be
+		// incomplete: fail closed rather than let a block re-use an uncle
+		// already claimed by that missing ancestor.
+		ancestorBlock := c.API.Chain.GetBlock(task.Hash, task.Header.Number.Uint64())
+		if ancestorBlock == nil {
+			c.API.SendVerifyUnclesResponse(consensus.VerifyUnclesResponse{req.ID, req.Block.Hash(), errAncestorBlockUnknown})
+			return
+		}
+
+		for _, uncle := range ancestorBlock.Uncles() {
+			req.Uncles.Add(uncle.Hash())
+		}
+	}
+
+	select {
+	case c.uncleTasks <- uncleVerifyTask{req}:
+	default:
+		c.API.SendVerifyUnclesResponse(consensus.VerifyUnclesResponse{req.ID, req.Block.Hash(), errVerifyQueueFull})
+	}
+}
+
+// verifyUncleTask runs on a verifyWorker goroutine: it makes the CPU-bound
+// Server.VerifyUncles call and reports the result.
+func (c *Consensus) verifyUncleTask(task uncleVerifyTask) {
+	req := task.Req
+
+	err := c.Server.VerifyUncles(c.API.Chain, req.Block, req.Ancestors, req.Uncles)
+	c.API.SendVerifyUnclesResponse(consensus.VerifyUnclesResponse{req.ID, req.Block.Hash(), err})
+}
+
+func (c *Consensus) VerifyRequestsCommonAncestor(reqID uint64, headers []consensus.HeaderTask) {
 	if len(headers) == 0 {
 		return
 	}
@@ -179,8 +498,8 @@ func (c *Consensus) VerifyRequestsCommonAncestor(reqID uint64, headers []*types.
 		return nums[i] < nums[j]
 	})
 
-	for _, header := range headers {
-		c.API.CacheHeader(header)
+	for _, task := range headers {
+		c.API.CacheHeader(task)
 	}
 
 	knownByRequests := make(map[uint64]map[common.Hash]map[uint64]struct{}) // reqID -> parenthash -> blockToValidate
@@ -192,34 +511,107 @@ func (c *Consensus) VerifyRequestsCommonAncestor(reqID uint64, headers []*types.
 
 		appendAncestors(req, headers, knownByRequests)
 
-		err := c.verifyByRequest(req.ID, req.Header, req.Seal, req.ParentsExpected, req.KnownParents)
+		// Block on the real Verify result (not just a successful hand-off)
+		// before trusting req.Header as a resolved parent for later, higher
+		// numbered headers in this same batch.
+		err := c.verifyByRequestSync(req.ID, req.Header, req.Seal, req.ParentsExpected, req.KnownParents)
 		if err == nil {
 			headers = append(headers, req.Header)
 		}
 	}
 }
 
-func (c *Consensus) verifyByRequest(reqID uint64, header *types.Header, seal bool, parentsExpected int, knownParents []*types.Header) error {
+// verifyTask is a header whose parents are all resolved and is ready for
+// the (CPU-bound) Server.Verify call, handed off from the coordinator to
+// the worker pool. Done, if non-nil, receives the actual Verify result so a
+// caller can wait for the real outcome instead of just the hand-off.
+type verifyTask struct {
+	ReqID        uint64
+	Header       consensus.HeaderTask
+	Seal         bool
+	KnownParents []consensus.HeaderTask
+	Done         chan error
+}
+
+// verifyByRequest runs on the coordinator goroutine. Once knownParents is
+// complete it hands the header to the worker pool and returns immediately;
+// it does not itself block on Verify, so a nil error here only means the
+// header was accepted onto the queue, not that it verified successfully.
+func (c *Consensus) verifyByRequest(reqID uint64, task consensus.HeaderTask, seal bool, parentsExpected int, knownParents []consensus.HeaderTask) error {
+	if len(knownParents) != parentsExpected {
+		return errNotAllParents
+	}
+
+	select {
+	case c.verifyTasks <- verifyTask{reqID, task, seal, knownParents, nil}:
+	default:
+		c.API.SendVerifyHeaderResponse(consensus.VerifyHeaderResponse{reqID, task.Hash, errVerifyQueueFull})
+		return errVerifyQueueFull
+	}
+
+	return nil
+}
+
+// verifyByRequestSync behaves like verifyByRequest but blocks until the
+// worker pool has actually run Server.Verify, returning its real result.
+// Callers that chain one header's verification into another header's
+// KnownParents (VerifyRequestsCommonAncestor) need this: trusting a header
+// as a resolved ancestor before its own Verify call has even run would let
+// an invalid header poison everything chained after it in the same batch.
+func (c *Consensus) verifyByRequestSync(reqID uint64, task consensus.HeaderTask, seal bool, parentsExpected int, knownParents []consensus.HeaderTask) error {
 	if len(knownParents) != parentsExpected {
 		return errNotAllParents
 	}
 
-	err := c.Server.Verify(c.API.Chain, header, knownParents, false, seal)
+	done := make(chan error, 1)
+
+	select {
+	case c.verifyTasks <- verifyTask{reqID, task, seal, knownParents, done}:
+	default:
+		c.API.SendVerifyHeaderResponse(consensus.VerifyHeaderResponse{reqID, task.Hash, errVerifyQueueFull})
+		return errVerifyQueueFull
+	}
+
+	return <-done
+}
+
+func (c *Consensus) verifyWorker(exit chan struct{}) {
+	for {
+		select {
+		case task := <-c.verifyTasks:
+			c.verify(task)
+		case task := <-c.uncleTasks:
+			c.verifyUncleTask(task)
+		case <-exit:
+			return
+		}
+	}
+}
+
+func (c *Consensus) verify(task verifyTask) {
+	parents := make([]*types.Header, len(task.KnownParents))
+	for i, p := range task.KnownParents {
+		parents[i] = p.Header
+	}
+
+	err := c.Server.Verify(c.API.Chain, task.Header.Header, parents, false, task.Seal)
 	if err == nil {
-		c.API.CacheHeader(header)
+		c.API.CacheHeader(task.Header)
 	}
 
-	c.API.VerifyHeaderResponses <- consensus.VerifyHeaderResponse{reqID, header.Hash(), err}
+	c.API.SendVerifyHeaderResponse(consensus.VerifyHeaderResponse{task.ReqID, task.Header.Hash, err})
+
+	if task.Done != nil {
+		task.Done <- err
+	}
 
 	// remove finished request
-	finishedRequest := consensus.FinishedRequest{reqID, header.Number.Uint64()}
+	finishedRequest := consensus.FinishedRequest{task.ReqID, task.Header.Header.Number.Uint64()}
 	select {
 	case c.CleanupCh <- finishedRequest:
 	default:
 		c.cleanupRequest(finishedRequest.ReqID, finishedRequest.BlockNumber)
 	}
-
-	return nil
 }
 
 // remove finished request
@@ -227,7 +619,10 @@ func (c *Consensus) cleanupRequest(reqID uint64, number uint64) {
 	c.API.ProcessingRequestsMu.Lock()
 	reqBlocks, ok := c.API.ProcessingRequests[reqID]
 	if ok {
-		delete(reqBlocks, number)
+		if req, found := reqBlocks[number]; found {
+			close(req.Done)
+			delete(reqBlocks, number)
+		}
 		if len(reqBlocks) == 0 {
 			delete(c.API.ProcessingRequests, reqID)
 		}
@@ -235,21 +630,23 @@ func (c *Consensus) cleanupRequest(reqID uint64, number uint64) {
 	c.API.ProcessingRequestsMu.Unlock()
 }
 
-func toVerifyRequest(reqID uint64, header *types.Header, seal bool, deadline *time.Time, knownParents []*types.Header, parentsToValidate int) *consensus.VerifyRequest {
+func toVerifyRequest(reqID uint64, task consensus.HeaderTask, seal bool, deadline *time.Time, ctx context.Context, knownParents []consensus.HeaderTask, parentsToValidate int) *consensus.VerifyRequest {
 	return &consensus.VerifyRequest{
 		reqID,
-		header,
+		task,
 		seal,
 		deadline,
+		ctx,
+		make(chan struct{}),
 		knownParents,
 		parentsToValidate,
-		header.Number.Uint64() - uint64(parentsToValidate),
-		header.Number.Uint64() - uint64(len(knownParents)) - 1,
+		task.Header.Number.Uint64() - uint64(parentsToValidate),
+		task.Header.Number.Uint64() - uint64(len(knownParents)) - 1,
 	}
 }
 
-func (c *Consensus) addVerifyHeaderRequest(reqID uint64, header *types.Header, seal bool, deadline *time.Time, knownParentsSlice []*types.Header, parentsToValidate int) {
-	request := toVerifyRequest(reqID, header, seal, deadline, knownParentsSlice, parentsToValidate)
+func (c *Consensus) addVerifyHeaderRequest(reqID uint64, task consensus.HeaderTask, seal bool, deadline *time.Time, ctx context.Context, knownParentsSlice []consensus.HeaderTask, parentsToValidate int) {
+	request := toVerifyRequest(reqID, task, seal, deadline, ctx, knownParentsSlice, parentsToValidate)
 
 	c.API.ProcessingRequestsMu.Lock()
 	blocks, ok := c.API.ProcessingRequests[reqID]
@@ -257,19 +654,42 @@ func (c *Consensus) addVerifyHeaderRequest(reqID uint64, header *types.Header, s
 		blocks = make(map[uint64]*consensus.VerifyRequest)
 	}
 
-	blocks[header.Number.Uint64()] = request
+	blocks[task.Header.Number.Uint64()] = request
 	c.API.ProcessingRequests[reqID] = blocks
 	c.API.ProcessingRequestsMu.Unlock()
+
+	if ctx != nil {
+		go c.watchCancellation(reqID, task.Header.Number.Uint64(), ctx, request.Done)
+	}
+}
+
+// watchCancellation forwards ctx's cancellation onto CancelledCh so the
+// coordinator can drop the pending request immediately instead of waiting
+// for CleanupTicker to notice its Deadline. done is the request's own
+// VerifyRequest.Done, closed once the request is removed from
+// ProcessingRequests through any path; watchCancellation exits as soon as
+// that happens instead of leaking for the rest of ctx's lifetime.
+func (c *Consensus) watchCancellation(reqID uint64, number uint64, ctx context.Context, done chan struct{}) {
+	select {
+	case <-ctx.Done():
+		select {
+		case c.API.CancelledCh <- consensus.CancelledRequest{reqID, number, ctx.Err()}:
+		case <-done:
+		case <-c.exit:
+		}
+	case <-done:
+	case <-c.exit:
+	}
 }
 
-func appendAncestors(request *consensus.VerifyRequest, ancestors []*types.Header, knownByRequests map[uint64]map[common.Hash]map[uint64]struct{}) {
-	blockNumber := request.Header.Number.Uint64()
+func appendAncestors(request *consensus.VerifyRequest, ancestors []consensus.HeaderTask, knownByRequests map[uint64]map[common.Hash]map[uint64]struct{}) {
+	blockNumber := request.Header.Header.Number.Uint64()
 
 	ancestorsMap, ok := knownByRequests[request.ID]
 	if !ok {
 		ancestorsMap = make(map[common.Hash]map[uint64]struct{}, len(request.KnownParents)+len(ancestors))
 		for _, p := range request.KnownParents {
-			ancestorsMap[p.Hash()] = map[uint64]struct{}{
+			ancestorsMap[p.Hash] = map[uint64]struct{}{
 				blockNumber: {},
 			}
 		}
@@ -277,10 +697,11 @@ func appendAncestors(request *consensus.VerifyRequest, ancestors []*types.Header
 	}
 
 	for _, parent := range ancestors {
-		if parent.Number.Uint64() >= request.From && parent.Number.Uint64() <= request.To {
-			parentMap, has := ancestorsMap[parent.Hash()]
+		parentNumber := parent.Header.Number.Uint64()
+		if parentNumber >= request.From && parentNumber <= request.To {
+			parentMap, has := ancestorsMap[parent.Hash]
 			if !has {
-				ancestorsMap[parent.Hash()] = map[uint64]struct{}{
+				ancestorsMap[parent.Hash] = map[uint64]struct{}{
 					blockNumber: {},
 				}
 
@@ -288,7 +709,7 @@ func appendAncestors(request *consensus.VerifyRequest, ancestors []*types.Header
 			} else {
 				_, has = parentMap[blockNumber]
 				if !has {
-					ancestorsMap[parent.Hash()] = map[uint64]struct{}{
+					ancestorsMap[parent.Hash] = map[uint64]struct{}{
 						blockNumber: {},
 					}
 					request.KnownParents = append(request.KnownParents, parent)
@@ -302,17 +723,37 @@ func (c *Consensus) HeaderVerification() chan<- consensus.VerifyHeaderRequest {
 	return c.API.VerifyHeaderRequests
 }
 
-func (c *Consensus) requestParentHeaders(reqID uint64, header *types.Header, reqHeaders []*types.Header) ([]*types.Header, int, *consensus.HeadersRequest) {
-	parentsToValidate := c.Server.NeededForVerification(header)
+// HeaderVerificationWithContext submits req with ctx attached, so it can be
+// cancelled before req.Deadline. Under consensus.ResponsePolicyReject it
+// returns consensus.ErrResponseChannelFull instead of submitting a request
+// whose response has nowhere to land.
+func (c *Consensus) HeaderVerificationWithContext(ctx context.Context, req consensus.VerifyHeaderRequest) error {
+	if c.API.ResponsePolicy == consensus.ResponsePolicyReject && c.API.ResponseBufferFull() {
+		return consensus.ErrResponseChannelFull
+	}
+
+	req.Context = ctx
+	c.API.VerifyHeaderRequests <- req
+
+	return nil
+}
+
+func (c *Consensus) UnclesVerification() chan<- consensus.VerifyUnclesRequest {
+	return c.API.VerifyUnclesRequests
+}
+
+func (c *Consensus) requestParentHeaders(reqID uint64, task consensus.HeaderTask, reqHeaders []consensus.HeaderTask) ([]consensus.HeaderTask, int, *consensus.HeadersRequest) {
+	parentsToValidate := c.Server.NeededForVerification(task.Header)
 	if parentsToValidate == 0 {
 		return nil, 0, nil
 	}
 
+	header := task.Header
 	headerNumber := header.Number.Uint64()
 	headerParentHash := header.ParentHash
 
-	from := reqHeaders[0].Number.Uint64()
-	to := reqHeaders[len(reqHeaders)-1].Number.Uint64()
+	from := reqHeaders[0].Header.Number.Uint64()
+	to := reqHeaders[len(reqHeaders)-1].Header.Number.Uint64()
 
 	parentsToAsk := parentsToValidate
 
@@ -328,11 +769,11 @@ func (c *Consensus) requestParentHeaders(reqID uint64, header *types.Header, req
 
 	if parentsToAsk > 0 {
 		headerNumber = from - 1
-		headerParentHash = reqHeaders[0].ParentHash
+		headerParentHash = reqHeaders[0].Header.ParentHash
 	}
 
 	knownParents, ancestorsReq := c.requestHeadersNotFromRange(reqID, headerNumber, headerParentHash, uint64(parentsToAsk))
-	knownParentsFromRange := c.checkHeadersFromRange(header, reqHeaders, uint64(parentsToAsk), uint64(parentsToValidate))
+	knownParentsFromRange := c.checkHeadersFromRange(task, reqHeaders, uint64(parentsToAsk), uint64(parentsToValidate))
 
 	knownParents = append(knownParents, knownParentsFromRange...)
 
@@ -341,7 +782,7 @@ func (c *Consensus) requestParentHeaders(reqID uint64, header *types.Header, req
 
 var errNotAllParents = errors.New("not all parents are gathered")
 
-func (c *Consensus) requestHeadersNotFromRange(reqID uint64, highestBlock uint64, highestKnown common.Hash, parentsToGet uint64) ([]*types.Header, consensus.HeadersRequest) {
+func (c *Consensus) requestHeadersNotFromRange(reqID uint64, highestBlock uint64, highestKnown common.Hash, parentsToGet uint64) ([]consensus.HeaderTask, consensus.HeadersRequest) {
 	highestParentHash := highestKnown
 	highestParentNumber := highestBlock
 
@@ -350,21 +791,27 @@ func (c *Consensus) requestHeadersNotFromRange(reqID uint64, highestBlock uint64
 		minHeader = highestBlock - parentsToGet + 1
 	}
 
-	known := make([]*types.Header, 0, highestBlock-minHeader)
+	known := make([]consensus.HeaderTask, 0, highestBlock-minHeader)
 
 	for parentBlockNum := highestBlock; parentBlockNum >= minHeader; parentBlockNum-- {
-		parentBlock := c.API.GetCachedHeader(highestKnown, parentBlockNum)
+		parentHash := highestKnown
+
+		parentBlock := c.API.GetCachedHeader(parentHash, parentBlockNum)
 		if parentBlock == nil {
 			break
 		}
 
+		// parentHash is already parentBlock's hash (that's what it was
+		// looked up by); reuse it instead of recomputing via Hash().
+		task := consensus.HeaderTask{Header: parentBlock, Hash: parentHash}
+
 		highestKnown = parentBlock.ParentHash
 
-		known = append(known, parentBlock)
+		known = append(known, task)
 
 		if highestParentNumber < parentBlock.Number.Uint64() {
 			highestParentNumber = parentBlock.Number.Uint64()
-			highestParentHash = parentBlock.Hash()
+			highestParentHash = task.Hash
 		}
 	}
 
@@ -412,7 +859,7 @@ func sumHeadersRequestsInRange(reqID uint64, from uint64, reqs ...consensus.Head
 	}, nil
 }
 
-func (c *Consensus) checkHeadersFromRange(highestHeader *types.Header, requestedHeaders []*types.Header, parentsToGet, parentsToValidate uint64) []*types.Header {
+func (c *Consensus) checkHeadersFromRange(highestTask consensus.HeaderTask, requestedHeaders []consensus.HeaderTask, parentsToGet, parentsToValidate uint64) []consensus.HeaderTask {
 	parentsToGet = parentsToValidate - parentsToGet
 	if parentsToGet <= 0 {
 		return nil
@@ -420,7 +867,7 @@ func (c *Consensus) checkHeadersFromRange(highestHeader *types.Header, requested
 
 	idx := -1
 	for i, h := range requestedHeaders {
-		if h.Number.Uint64() == highestHeader.Number.Uint64() {
+		if h.Header.Number.Uint64() == highestTask.Header.Number.Uint64() {
 			idx = i
 			break
 		}
@@ -438,4 +885,8 @@ func (c *Consensus) checkHeadersFromRange(highestHeader *types.Header, requested
 
 func (c *Consensus) VerifyResults() <-chan consensus.VerifyHeaderResponse {
 	return c.API.VerifyHeaderResponses
-}
\ No newline at end of file
+}
+
+func (c *Consensus) VerifyUnclesResults() <-chan consensus.VerifyUnclesResponse {
+	return c.API.VerifyUnclesResponses
+}
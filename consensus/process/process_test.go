@@ -0,0 +1,84 @@
+package process
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// blockingVerifier lets a test observe when Verify or VerifyUncles starts
+// and control when it returns, so concurrency within the worker pool can be
+// asserted.
+type blockingVerifier struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (v *blockingVerifier) NeededForVerification(*types.Header) int { return 0 }
+
+func (v *blockingVerifier) Verify(consensus.ChainReader, *types.Header, []*types.Header, bool, bool) error {
+	v.started <- struct{}{}
+	<-v.release
+	return nil
+}
+
+func (v *blockingVerifier) VerifyUncles(consensus.ChainReader, *types.Block, consensus.HashSet, consensus.HashSet) error {
+	v.started <- struct{}{}
+	<-v.release
+	return nil
+}
+
+func newTask(number int64) consensus.HeaderTask {
+	header := &types.Header{Number: big.NewInt(number)}
+	return consensus.NewHeaderTask(header)
+}
+
+// TestVerifyWorkerPoolRunsTasksConcurrently submits more verifyTasks than a
+// single goroutine could run at once and asserts the pool actually
+// processes them in parallel rather than serializing them the way a single
+// coordinator goroutine would.
+func TestVerifyWorkerPoolRunsTasksConcurrently(t *testing.T) {
+	v := &blockingVerifier{
+		started: make(chan struct{}, 2),
+		release: make(chan struct{}),
+	}
+
+	exit := make(chan struct{})
+	defer close(exit)
+
+	c := NewConsensusProcess(v, &params.ChainConfig{}, exit, WithWorkerPoolSize(2))
+
+	done1 := make(chan error, 1)
+	done2 := make(chan error, 1)
+	c.verifyTasks <- verifyTask{1, newTask(1), false, nil, done1}
+	c.verifyTasks <- verifyTask{2, newTask(2), false, nil, done2}
+
+	// Both workers must reach Verify before either is released: if the pool
+	// were really a single goroutine the second start would never arrive
+	// while the first Verify call is still blocked.
+	timeout := time.After(2 * time.Second)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-v.started:
+		case <-timeout:
+			t.Fatalf("only %d/2 workers started concurrently", i)
+		}
+	}
+
+	close(v.release)
+
+	for _, done := range []chan error{done1, done2} {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("unexpected verify error: %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for verify result")
+		}
+	}
+}
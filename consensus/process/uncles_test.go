@@ -0,0 +1,152 @@
+package process
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// nilBlockChain is a ChainReader whose GetBlock always reports the block as
+// not locally available, simulating an ancestor this node hasn't imported.
+type nilBlockChain struct{}
+
+func (nilBlockChain) Config() *params.ChainConfig                 { return nil }
+func (nilBlockChain) CurrentHeader() *types.Header                { return nil }
+func (nilBlockChain) GetHeader(common.Hash, uint64) *types.Header { return nil }
+func (nilBlockChain) GetBlock(common.Hash, uint64) *types.Block   { return nil }
+
+// TestVerifyUnclesHandsOffToWorkerPool checks that verifyUncles, once it has
+// resolved the ancestor/uncle sets, enqueues the actual Server.VerifyUncles
+// call onto the worker pool and returns immediately rather than running it
+// inline on the coordinator.
+func TestVerifyUnclesHandsOffToWorkerPool(t *testing.T) {
+	v := &blockingVerifier{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+
+	exit := make(chan struct{})
+	defer close(exit)
+
+	c := NewConsensusProcess(v, &params.ChainConfig{}, exit)
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10)})
+	req := &consensus.UnclesVerifyRequest{
+		ID:        42,
+		Block:     block,
+		Ancestors: consensus.NewHashSet(),
+		Uncles:    consensus.NewHashSet(),
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.verifyUncles(req, consensus.HeaderResponse{ID: 42})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("verifyUncles blocked instead of handing off to the worker pool")
+	}
+
+	select {
+	case <-v.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("worker pool never invoked Server.VerifyUncles")
+	}
+
+	close(v.release)
+
+	select {
+	case resp := <-c.VerifyUnclesResults():
+		if resp.ID != 42 || resp.Err != nil {
+			t.Fatalf("unexpected response: %+v", resp)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for VerifyUnclesResults")
+	}
+}
+
+// TestVerifyUnclesFailsClosedOnMissingAncestorBlock reproduces the scenario
+// where a resolved ancestor header doesn't correspond to a block this node
+// has imported yet: verifyUncles must fail the check rather than silently
+// verify against an incomplete includedUncles set.
+func TestVerifyUnclesFailsClosedOnMissingAncestorBlock(t *testing.T) {
+	v := &blockingVerifier{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	close(v.release)
+
+	exit := make(chan struct{})
+	defer close(exit)
+
+	c := NewConsensusProcess(v, &params.ChainConfig{}, exit)
+	c.API.Chain = nilBlockChain{}
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10)})
+	req := &consensus.UnclesVerifyRequest{
+		ID:        7,
+		Block:     block,
+		Ancestors: consensus.NewHashSet(),
+		Uncles:    consensus.NewHashSet(),
+	}
+
+	ancestor := consensus.NewHeaderTask(&types.Header{Number: big.NewInt(9)})
+	c.verifyUncles(req, consensus.HeaderResponse{ID: 7, Headers: []consensus.HeaderTask{ancestor}})
+
+	select {
+	case resp := <-c.VerifyUnclesResults():
+		if !errors.Is(resp.Err, errAncestorBlockUnknown) {
+			t.Fatalf("expected errAncestorBlockUnknown, got %v", resp.Err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for VerifyUnclesResults")
+	}
+}
+
+// TestRequestUncleAncestorsRegisters checks that requestUncleAncestors
+// registers the request in ProcessingUnclesRequests before dispatching the
+// ancestor HeadersRequest, so a later HeaderResponse can find it.
+func TestRequestUncleAncestorsRegisters(t *testing.T) {
+	v := &blockingVerifier{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	close(v.release)
+
+	exit := make(chan struct{})
+	defer close(exit)
+
+	c := NewConsensusProcess(v, &params.ChainConfig{}, exit)
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10), ParentHash: common.Hash{1}})
+
+	drained := make(chan struct{})
+	go func() {
+		select {
+		case <-c.API.HeadersRequests:
+		case <-time.After(2 * time.Second):
+		}
+		close(drained)
+	}()
+
+	c.requestUncleAncestors(consensus.VerifyUnclesRequest{ID: 3, Block: block})
+
+	c.API.ProcessingUnclesRequestsMu.Lock()
+	_, tracked := c.API.ProcessingUnclesRequests[3]
+	c.API.ProcessingUnclesRequestsMu.Unlock()
+
+	if !tracked {
+		t.Fatal("requestUncleAncestors did not register the request in ProcessingUnclesRequests")
+	}
+
+	<-drained
+}
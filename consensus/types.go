@@ -0,0 +1,136 @@
+package consensus
+
+import (
+	"context"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+)
+
+// HeaderTask bundles a header with its RLP hash, computed once by the
+// producer (typically the p2p peer reader goroutine) instead of being
+// recomputed by every consumer down the verification pipeline. This mirrors
+// the headerTask go-ethereum's downloader uses for the same reason.
+type HeaderTask struct {
+	Header *types.Header
+	Hash   common.Hash
+}
+
+// NewHeaderTask wraps header, computing its hash exactly once.
+func NewHeaderTask(header *types.Header) HeaderTask {
+	return HeaderTask{Header: header, Hash: header.Hash()}
+}
+
+// VerifyHeaderRequest is sent on API.VerifyHeaderRequests to ask the
+// consensus process to verify a batch of headers. Context, if non-nil, is
+// carried into every ProcessingRequests entry spawned from this request so
+// the caller can cancel before Deadline without waiting for CleanupTicker.
+type VerifyHeaderRequest struct {
+	ID       uint64
+	Headers  []HeaderTask
+	Seal     []bool
+	Deadline *time.Time
+	Context  context.Context
+}
+
+// VerifyHeaderResponse is the result of verifying a single header from a
+// VerifyHeaderRequest.
+type VerifyHeaderResponse struct {
+	ID   uint64
+	Hash common.Hash
+	Err  error
+}
+
+// HeadersRequest asks the p2p layer to fetch ancestor headers in
+// [HighestBlockNumber-Number+1, HighestBlockNumber], identified by the hash
+// of the highest block.
+type HeadersRequest struct {
+	ID                 uint64
+	HighestHash        common.Hash
+	HighestBlockNumber uint64
+	Number             uint64
+}
+
+// HeaderResponse delivers the ancestor headers requested via HeadersRequest.
+// PeerID identifies the responder when the request was dispatched to a
+// specific peer rather than broadcast; it is empty otherwise.
+type HeaderResponse struct {
+	ID      uint64
+	Hash    common.Hash
+	Err     error
+	Headers []HeaderTask
+	PeerID  string
+}
+
+// PeerHeadersRequest is a HeadersRequest routed to one specific peer that
+// announced coverage of the requested range, rather than broadcast blindly.
+type PeerHeadersRequest struct {
+	HeadersRequest
+	PeerID string
+}
+
+// CancelHeadersRequest tells the p2p layer to stop waiting on Peers for
+// ID; sent once another peer's response has already satisfied the request.
+type CancelHeadersRequest struct {
+	ID    uint64
+	Peers []string
+}
+
+// FinishedRequest marks a single block within a request as done, so it can
+// be dropped from API.ProcessingRequests.
+type FinishedRequest struct {
+	ReqID       uint64
+	BlockNumber uint64
+}
+
+// VerifyRequest is the bookkeeping entry kept in API.ProcessingRequests for
+// a header whose ancestors are not fully known yet. Done is closed once the
+// request leaves ProcessingRequests through any path (verified, timed out,
+// or explicitly cleaned up), so a watcher goroutine tied to Context can stop
+// waiting instead of leaking for the lifetime of Context.
+type VerifyRequest struct {
+	ID              uint64
+	Header          HeaderTask
+	Seal            bool
+	Deadline        *time.Time
+	Context         context.Context
+	Done            chan struct{}
+	KnownParents    []HeaderTask
+	ParentsExpected int
+	From            uint64
+	To              uint64
+}
+
+// CancelledRequest signals that a VerifyRequest's Context was cancelled
+// before CleanupTicker next ran, so the coordinator can drop it immediately.
+type CancelledRequest struct {
+	ReqID       uint64
+	BlockNumber uint64
+	Err         error
+}
+
+// VerifyUnclesRequest is sent on API.VerifyUnclesRequests to ask the
+// consensus process to check block's uncles against its recent ancestry.
+type VerifyUnclesRequest struct {
+	ID       uint64
+	Block    *types.Block
+	Deadline *time.Time
+}
+
+// VerifyUnclesResponse is the result of a VerifyUnclesRequest.
+type VerifyUnclesResponse struct {
+	ID   uint64
+	Hash common.Hash
+	Err  error
+}
+
+// UnclesVerifyRequest is the bookkeeping entry kept in
+// API.ProcessingUnclesRequests while a block's ancestors are being fetched.
+type UnclesVerifyRequest struct {
+	ID        uint64
+	Block     *types.Block
+	Deadline  *time.Time
+	Ancestors HashSet
+	Uncles    HashSet
+}
@@ -0,0 +1,37 @@
+package consensus
+
+import (
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// Verifier is the engine-specific backend (ethash, clique, ...) that the
+// consensus process delegates actual header/seal checks to.
+type Verifier interface {
+	// NeededForVerification returns how many direct ancestors of header are
+	// required before it can be verified. Zero means header can be verified
+	// on its own.
+	NeededForVerification(header *types.Header) int
+
+	// Verify checks header against chain, given its already-resolved
+	// parents in the order they were gathered; they are not sorted by
+	// number. uncle indicates header is being verified as part of an uncle
+	// set rather than the canonical chain.
+	Verify(chain ChainReader, header *types.Header, parents []*types.Header, uncle bool, seal bool) error
+
+	// VerifyUncles checks that block's uncles are valid: each must be a
+	// recent, not-yet-included ancestor side-block. ancestors is the set of
+	// the last few canonical ancestors of block's parent, and includedUncles
+	// is the set of uncle hashes those ancestors have already claimed.
+	VerifyUncles(chain ChainReader, block *types.Block, ancestors HashSet, includedUncles HashSet) error
+}
+
+// ChainReader is the minimal chain view the verifier backends need to look
+// up ancestors that were not supplied directly in a verification request.
+type ChainReader interface {
+	Config() *params.ChainConfig
+	CurrentHeader() *types.Header
+	GetHeader(hash common.Hash, number uint64) *types.Header
+	GetBlock(hash common.Hash, number uint64) *types.Block
+}